@@ -0,0 +1,304 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+// batchOpType identifies the kind of staged operation held in a batchOp
+type batchOpType uint8
+
+const (
+	batchOpUpdate batchOpType = iota
+	batchOpAdd
+	batchOpAddWithID
+	batchOpRemove
+)
+
+// batchOp is a single staged operation within a Batch
+type batchOp struct {
+	kind   batchOpType
+	path   string
+	id     string
+	data   interface{}
+	strict bool
+}
+
+// Batch stages a set of Update/Add/Remove operations against a single transaction
+// branch so they can be committed, or rolled back, as one atomic unit.
+type Batch struct {
+	proxy *Proxy
+	txid  string
+	ops   []batchOp
+	paths map[string]struct{}
+	done  bool
+}
+
+// BatchConflictError reports the paths that could not be committed atomically,
+// either because one of the batch's own operations failed or because a
+// concurrent commit already touched an overlapping path.
+type BatchConflictError struct {
+	Paths []string
+}
+
+func (e *BatchConflictError) Error() string {
+	return fmt.Sprintf("batch conflict on path(s): %s", strings.Join(e.Paths, ", "))
+}
+
+// Batch opens a new transaction branch and returns a builder that stages
+// operations against it until Commit or Rollback is called.
+func (p *Proxy) Batch(ctx context.Context) *Batch {
+	txid := p.getRoot().MakeTxBranch()
+	return &Batch{
+		proxy: p,
+		txid:  txid,
+		paths: make(map[string]struct{}),
+	}
+}
+
+// Update stages a modification of path to be applied when the batch commits.
+func (b *Batch) Update(path string, data interface{}, strict bool) *Batch {
+	b.stage(batchOp{kind: batchOpUpdate, path: path, data: data, strict: strict})
+	return b
+}
+
+// Add stages the insertion of data at path to be applied when the batch commits.
+func (b *Batch) Add(path string, data interface{}) *Batch {
+	b.stage(batchOp{kind: batchOpAdd, path: path, data: data})
+	return b
+}
+
+// AddWithID stages the insertion of data at path under the given id.
+func (b *Batch) AddWithID(path string, id string, data interface{}) *Batch {
+	b.stage(batchOp{kind: batchOpAddWithID, path: path, id: id, data: data})
+	return b
+}
+
+// Remove stages the deletion of path to be applied when the batch commits.
+func (b *Batch) Remove(path string) *Batch {
+	b.stage(batchOp{kind: batchOpRemove, path: path})
+	return b
+}
+
+func (b *Batch) stage(op batchOp) {
+	b.ops = append(b.ops, op)
+	b.paths[b.fullPath(op.path)] = struct{}{}
+}
+
+// fullPath resolves path, which is relative to b.proxy the same way a staged
+// op's path is, into the absolute path used to key the conflict registry.
+func (b *Batch) fullPath(path string) string {
+	if path == "/" {
+		return b.proxy.getFullPath()
+	}
+	return b.proxy.getFullPath() + path
+}
+
+// Commit applies every staged operation against the batch's transaction branch
+// and, if all of them succeed and no conflicting concurrent commit touched an
+// overlapping path, folds the branch into the data model as a single atomic
+// unit. On any failure the branch is discarded via DeleteTxBranch and a
+// *BatchConflictError listing the offending paths is returned.
+func (b *Batch) Commit(ctx context.Context) ([]interface{}, error) {
+	if b.done {
+		return nil, fmt.Errorf("batch already committed or rolled back")
+	}
+	b.done = true
+
+	registry := getBatchRegistry(b.proxy.getRoot())
+	if conflicts := registry.checkAndRegister(b.paths, b.txid); len(conflicts) > 0 {
+		b.proxy.getRoot().DeleteTxBranch(b.txid)
+		return nil, &BatchConflictError{Paths: conflicts}
+	}
+	defer registry.unregister(b.txid)
+
+	results := make([]interface{}, 0, len(b.ops))
+	changes := make([]appliedChange, 0, len(b.ops))
+	var failed []string
+
+	b.proxy.beginBatch()
+	defer b.proxy.endBatch()
+
+	for _, op := range b.ops {
+		before := b.before(ctx, op)
+
+		result, err := b.apply(ctx, op)
+		if err != nil {
+			failed = append(failed, op.path)
+			continue
+		}
+		results = append(results, result)
+		changes = append(changes, appliedChange{path: op.path, old: before, new: result})
+	}
+
+	if len(failed) > 0 {
+		b.proxy.getRoot().DeleteTxBranch(b.txid)
+		return nil, &BatchConflictError{Paths: failed}
+	}
+
+	b.proxy.getRoot().FoldTxBranch(ctx, b.txid)
+	b.notifyAffectedSubtrees(ctx, changes)
+
+	return results, nil
+}
+
+// before returns the data op.path held immediately prior to op being applied,
+// for use as an appliedChange's old value. Add/AddWithID create a new item
+// under op.path rather than replacing whatever is already there, so op.path
+// itself names the parent collection, not the new item; there is nothing
+// meaningful to report as "old" for those, so before reports nil rather than
+// the unrelated collection data.
+func (b *Batch) before(ctx context.Context, op batchOp) interface{} {
+	if op.kind == batchOpAdd || op.kind == batchOpAddWithID {
+		return nil
+	}
+	before, _ := b.proxy.Get(ctx, op.path, 0, false, b.txid)
+	return before
+}
+
+// Rollback discards the batch's transaction branch without applying any of its
+// staged operations.
+func (b *Batch) Rollback() {
+	if b.done {
+		return
+	}
+	b.done = true
+	b.proxy.getRoot().DeleteTxBranch(b.txid)
+}
+
+func (b *Batch) apply(ctx context.Context, op batchOp) (interface{}, error) {
+	switch op.kind {
+	case batchOpUpdate:
+		return b.proxy.Update(ctx, op.path, op.data, op.strict, b.txid)
+	case batchOpAdd:
+		return b.proxy.Add(ctx, op.path, op.data, b.txid)
+	case batchOpAddWithID:
+		return b.proxy.AddWithID(ctx, op.path, op.id, op.data, b.txid)
+	case batchOpRemove:
+		return b.proxy.Remove(ctx, op.path, b.txid)
+	default:
+		return nil, fmt.Errorf("unknown batch operation kind: %d", op.kind)
+	}
+}
+
+// appliedChange is the before/after snapshot recorded for a single staged op
+// once it has applied successfully, used to build accurate Watch events.
+type appliedChange struct {
+	path string
+	old  interface{}
+	new  interface{}
+}
+
+// notifyAffectedSubtrees emits a single aggregated POST_UPDATE callback dispatch
+// per top-level subtree touched by the batch, rather than one per staged op,
+// carrying the real before/after data for every change within that subtree and
+// the subtree's own absolute path rather than the batch proxy's path.
+func (b *Batch) notifyAffectedSubtrees(ctx context.Context, changes []appliedChange) {
+	bySubtree := make(map[string][]appliedChange, len(changes))
+	for _, change := range changes {
+		subtree := commonSubtree(change.path)
+		bySubtree[subtree] = append(bySubtree[subtree], change)
+	}
+
+	for subtree, subtreeChanges := range bySubtree {
+		oldData := make([]interface{}, len(subtreeChanges))
+		newData := make([]interface{}, len(subtreeChanges))
+		for i, change := range subtreeChanges {
+			oldData[i] = change.old
+			newData[i] = change.new
+		}
+
+		subtreeProxy, err := b.proxy.CreateProxy(ctx, subtree, false)
+		if err != nil {
+			log.Errorw("batch-notify-subtree-failed", log.Fields{"subtree": subtree, "error": err})
+			continue
+		}
+		subtreeProxy.InvokeCallbacksAsync(context.Background(), POST_UPDATE, true, oldData, newData)
+	}
+}
+
+// commonSubtree returns the top-level segment of path, used to group staged
+// operations into the subtree that should receive a single aggregated callback.
+func commonSubtree(path string) string {
+	trimmed := strings.TrimPrefix(path, "/")
+	if idx := strings.Index(trimmed, "/"); idx >= 0 {
+		return "/" + trimmed[:idx]
+	}
+	return "/" + trimmed
+}
+
+// batchRegistry tracks the paths touched by in-flight batches for a given root so
+// overlapping concurrent commits can be detected.
+type batchRegistry struct {
+	mutex    sync.Mutex
+	inFlight map[string]map[string]struct{}
+}
+
+var batchRegistries = newRootResourceRegistry()
+
+func getBatchRegistry(r *root) *batchRegistry {
+	return batchRegistries.getOrCreate(r, func() interface{} {
+		return &batchRegistry{inFlight: make(map[string]map[string]struct{})}
+	}).(*batchRegistry)
+}
+
+// CloseRootBatches discards r's batch conflict registry, if any, so r can be
+// garbage collected. Any Batch still committing against r when this is called
+// loses its conflict protection.
+func CloseRootBatches(r *root) {
+	batchRegistries.remove(r)
+}
+
+// checkAndRegister reports the paths in paths that overlap with another
+// txid's in-flight paths and, only if there is no such conflict, registers
+// paths as in-flight under txid. Both steps run under a single critical
+// section so two concurrent commits racing over the same path can never both
+// observe a clean check and register themselves.
+func (br *batchRegistry) checkAndRegister(paths map[string]struct{}, txid string) []string {
+	br.mutex.Lock()
+	defer br.mutex.Unlock()
+
+	var conflicts []string
+	for otherTxid, otherPaths := range br.inFlight {
+		if otherTxid == txid {
+			continue
+		}
+		for path := range paths {
+			if _, overlap := otherPaths[path]; overlap {
+				conflicts = append(conflicts, path)
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return conflicts
+	}
+
+	br.inFlight[txid] = paths
+	return nil
+}
+
+func (br *batchRegistry) unregister(txid string) {
+	br.mutex.Lock()
+	defer br.mutex.Unlock()
+	delete(br.inFlight, txid)
+}
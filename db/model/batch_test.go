@@ -0,0 +1,133 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func Test_Batch_Stage_KeysPathsByAbsoluteProxyPath(t *testing.T) {
+	proxy := NewProxy(nil, nil, nil, "/devices", "/root/devices", false)
+	b := &Batch{proxy: proxy, paths: make(map[string]struct{})}
+
+	b.Update("/1", nil, false)
+
+	if _, ok := b.paths["/root/devices/1"]; !ok {
+		t.Errorf("expected the batch to key its staged path by the proxy's absolute path, got: %+v", b.paths)
+	}
+}
+
+func Test_Batch_Before_ReturnsNilForAddRatherThanParentCollection(t *testing.T) {
+	proxy := NewProxy(nil, nil, nil, "/devices", "/root/devices", false)
+	b := &Batch{proxy: proxy, paths: make(map[string]struct{})}
+
+	addBefore := b.before(context.Background(), batchOp{kind: batchOpAdd, path: "/"})
+	if addBefore != nil {
+		t.Errorf("expected before() to report nil for an Add op, got: %+v", addBefore)
+	}
+
+	addWithIDBefore := b.before(context.Background(), batchOp{kind: batchOpAddWithID, path: "/", id: "1"})
+	if addWithIDBefore != nil {
+		t.Errorf("expected before() to report nil for an AddWithID op, got: %+v", addWithIDBefore)
+	}
+}
+
+func Test_Proxy_BeginEndBatch_TracksInBatch(t *testing.T) {
+	proxy := NewProxy(nil, nil, nil, "/devices", "/root/devices", false)
+
+	if proxy.inBatch() {
+		t.Fatalf("a fresh proxy must not report inBatch")
+	}
+
+	proxy.beginBatch()
+	if !proxy.inBatch() {
+		t.Errorf("expected inBatch to be true after beginBatch")
+	}
+
+	proxy.endBatch()
+	if proxy.inBatch() {
+		t.Errorf("expected inBatch to be false after endBatch undoes beginBatch")
+	}
+}
+
+func Test_InvokeCallbacksAsync_SuppressedWhileProxyInBatch(t *testing.T) {
+	proxy := NewProxy(nil, nil, nil, "/devices", "/root/devices", false)
+	invoked := false
+	proxy.RegisterCallback(POST_UPDATE, func(ctx context.Context, args ...interface{}) interface{} {
+		invoked = true
+		return nil
+	})
+
+	proxy.beginBatch()
+	defer proxy.endBatch()
+
+	results := proxy.InvokeCallbacksAsync(context.Background(), POST_UPDATE, true)
+	for range results {
+	}
+
+	if invoked {
+		t.Errorf("expected InvokeCallbacksAsync to suppress dispatch while the proxy is in a batch")
+	}
+}
+
+func Test_BatchRegistry_CheckAndRegister_DetectsOverlap(t *testing.T) {
+	br := &batchRegistry{inFlight: make(map[string]map[string]struct{})}
+
+	pathsA := map[string]struct{}{"/root/devices/1": {}}
+	pathsB := map[string]struct{}{"/root/devices/1": {}}
+
+	if conflicts := br.checkAndRegister(pathsA, "txA"); len(conflicts) != 0 {
+		t.Fatalf("expected the first batch to register without conflict, got: %v", conflicts)
+	}
+
+	conflicts := br.checkAndRegister(pathsB, "txB")
+	if len(conflicts) != 1 || conflicts[0] != "/root/devices/1" {
+		t.Errorf("expected the overlapping path to be reported as a conflict, got: %v", conflicts)
+	}
+	if _, registered := br.inFlight["txB"]; registered {
+		t.Errorf("a conflicting batch must not be registered as in-flight")
+	}
+}
+
+// Test_BatchRegistry_CheckAndRegister_ConcurrentOverlapNeverBothSucceed guards
+// against the check-then-act race where two concurrent Commit calls both pass
+// the conflict check before either registers.
+func Test_BatchRegistry_CheckAndRegister_ConcurrentOverlapNeverBothSucceed(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		br := &batchRegistry{inFlight: make(map[string]map[string]struct{})}
+		paths := map[string]struct{}{"/root/devices/1": {}}
+
+		var wg sync.WaitGroup
+		successes := make([]bool, 2)
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				conflicts := br.checkAndRegister(paths, fmt.Sprintf("tx-%d", j))
+				successes[j] = len(conflicts) == 0
+			}(j)
+		}
+		wg.Wait()
+
+		if successes[0] && successes[1] {
+			t.Fatalf("expected at most one of two concurrent overlapping batches to register, both succeeded")
+		}
+	}
+}
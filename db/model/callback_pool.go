@@ -0,0 +1,191 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+// CallbackDispatchTimeout bounds how long a single callback may run once it has
+// been handed to a callbackWorkerPool before its context is cancelled
+const CallbackDispatchTimeout = 5 * time.Second
+
+const (
+	defaultCallbackWorkers = 8
+	defaultCallbackQueue = 256
+)
+
+// CallbackResult carries the outcome of a single callback invocation dispatched
+// through InvokeCallbacksAsync
+type CallbackResult struct {
+	FuncHash string
+	Result   interface{}
+	Error    error
+}
+
+// callbackJob is a unit of work submitted to a callbackWorkerPool
+type callbackJob struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	proxy    *Proxy
+	funcHash string
+	tuple    *CallbackTuple
+	args     []interface{}
+	results  chan<- CallbackResult
+}
+
+// callbackWorkerPool is a bounded pool of goroutines shared by every Proxy hanging
+// off the same root, used to run registered callbacks off the caller's goroutine
+type callbackWorkerPool struct {
+	jobs chan callbackJob
+}
+
+func newCallbackWorkerPool(workers int) *callbackWorkerPool {
+	if workers <= 0 {
+		workers = defaultCallbackWorkers
+	}
+	pool := &callbackWorkerPool{jobs: make(chan callbackJob, defaultCallbackQueue)}
+	for i := 0; i < workers; i++ {
+		go pool.run()
+	}
+	return pool
+}
+
+func (pool *callbackWorkerPool) run() {
+	for job := range pool.jobs {
+		// invoke runs in its own goroutine so a callback that ignores job.ctx
+		// and never returns can't park this worker forever: once job.ctx is
+		// done the worker abandons it and loops back to pool.jobs, leaving the
+		// runaway goroutine to finish (or not) on its own.
+		done := make(chan CallbackResult, 1)
+		go func() {
+			result, err := job.proxy.invoke(job.ctx, job.tuple, job.args)
+			done <- CallbackResult{FuncHash: job.funcHash, Result: result, Error: err}
+		}()
+
+		var cbResult CallbackResult
+		select {
+		case cbResult = <-done:
+		case <-job.ctx.Done():
+			cbResult = CallbackResult{FuncHash: job.funcHash, Error: job.ctx.Err()}
+		}
+
+		// job.results is buffered to hold one result per dispatched job, so this
+		// send never blocks; it is made even when job.ctx is done so a timed-out
+		// job still reports its error.
+		job.results <- cbResult
+		if job.cancel != nil {
+			job.cancel()
+		}
+	}
+}
+
+func (pool *callbackWorkerPool) submit(job callbackJob) {
+	pool.jobs <- job
+}
+
+var callbackPools = newRootResourceRegistry()
+
+func getCallbackWorkerPool(r *root) *callbackWorkerPool {
+	return callbackPools.getOrCreate(r, func() interface{} {
+		return newCallbackWorkerPool(defaultCallbackWorkers)
+	}).(*callbackWorkerPool)
+}
+
+// ConfigureCallbackWorkers overrides the number of workers used to dispatch
+// callbacks for every proxy sharing p's root. It has no effect once the pool for
+// that root has already been created.
+func (p *Proxy) ConfigureCallbackWorkers(workers int) {
+	callbackPools.getOrCreate(p.getRoot(), func() interface{} {
+		return newCallbackWorkerPool(workers)
+	})
+}
+
+// CloseRootCallbacks discards r's callback worker pool, if any, and stops its
+// worker goroutines so r can be garbage collected.
+func CloseRootCallbacks(r *root) {
+	entry := callbackPools.remove(r)
+	if entry == nil {
+		return
+	}
+	close(entry.(*callbackWorkerPool).jobs)
+}
+
+// InvokeCallbacksAsync snapshots the callbacks registered against callbackType
+// under p.mutex, releases the lock, then hands each one to the shared worker pool
+// owned by p's root with a per-callback timeout derived from ctx. The returned
+// channel receives one CallbackResult per dispatched callback and is closed once
+// all of them have completed, or as soon as one fails and proceedOnError is false.
+func (p *Proxy) InvokeCallbacksAsync(ctx context.Context, callbackType CallbackType, proceedOnError bool, args ...interface{}) <-chan CallbackResult {
+	if p.inBatch() {
+		// A Batch is applying staged ops against p; suppress the per-op
+		// dispatch this write would otherwise trigger so the batch's own
+		// aggregated notifyAffectedSubtrees call - made against a separate,
+		// non-suppressed subtree proxy once the batch folds - is the only one
+		// observed for this write.
+		results := make(chan CallbackResult)
+		close(results)
+		return results
+	}
+
+	p.notifyWatchers(callbackType, args)
+
+	p.mutex.RLock()
+	callbacks := make(map[string]*CallbackTuple, len(p.Callbacks[callbackType]))
+	for funcHash, tuple := range p.Callbacks[callbackType] {
+		callbacks[funcHash] = tuple
+	}
+	p.mutex.RUnlock()
+
+	results := make(chan CallbackResult, len(callbacks))
+	if len(callbacks) == 0 {
+		close(results)
+		return results
+	}
+
+	pool := getCallbackWorkerPool(p.getRoot())
+	pending := make(chan CallbackResult, len(callbacks))
+	for funcHash, tuple := range callbacks {
+		jobCtx, cancel := context.WithTimeout(ctx, CallbackDispatchTimeout)
+		pool.submit(callbackJob{
+			ctx:      jobCtx,
+			cancel:   cancel,
+			proxy:    p,
+			funcHash: funcHash,
+			tuple:    tuple,
+			args:     args,
+			results:  pending,
+		})
+	}
+
+	go func() {
+		defer close(results)
+		for i := 0; i < len(callbacks); i++ {
+			result := <-pending
+			results <- result
+			if result.Error != nil && !proceedOnError {
+				log.Info("An error occurred.  Stopping callback invocation")
+				return
+			}
+		}
+	}()
+
+	return results
+}
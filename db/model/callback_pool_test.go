@@ -0,0 +1,95 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test_CallbackWorkerPool_AbandonsCallbackThatIgnoresContext guards against a
+// callback that never returns and never observes ctx: the worker running it
+// must give up once the job's context times out and go back to picking up
+// work, rather than being parked on that one callback forever.
+func Test_CallbackWorkerPool_AbandonsCallbackThatIgnoresContext(t *testing.T) {
+	pool := newCallbackWorkerPool(1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	blocking := &CallbackTuple{callback: func(ctx context.Context, args ...interface{}) interface{} {
+		close(started)
+		<-release // deliberately ignores ctx, like a misbehaving callback
+		return "too-late"
+	}}
+
+	proxy := &Proxy{Callbacks: make(map[CallbackType]map[string]*CallbackTuple)}
+	firstCtx, firstCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer firstCancel()
+
+	firstResults := make(chan CallbackResult, 1)
+	pool.submit(callbackJob{ctx: firstCtx, cancel: firstCancel, proxy: proxy, funcHash: "blocking", tuple: blocking, results: firstResults})
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("blocking callback never started")
+	}
+
+	select {
+	case result := <-firstResults:
+		if result.Error == nil {
+			t.Errorf("expected a timed-out job to surface an error, got: %+v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("worker never gave up on the callback that ignored its context")
+	}
+
+	ok := &CallbackTuple{callback: func(ctx context.Context, args ...interface{}) interface{} { return "ok" }}
+	secondCtx, secondCancel := context.WithTimeout(context.Background(), time.Second)
+	defer secondCancel()
+
+	secondResults := make(chan CallbackResult, 1)
+	pool.submit(callbackJob{ctx: secondCtx, cancel: secondCancel, proxy: proxy, funcHash: "ok", tuple: ok, results: secondResults})
+
+	select {
+	case result := <-secondResults:
+		if result.Error != nil || result.Result != "ok" {
+			t.Errorf("expected a second job to complete normally once the worker gave up on the first, got: %+v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pool did not process a second job after the first one timed out")
+	}
+
+	close(release)
+}
+
+func Test_InvokeCallbacksAsync_StopsOnErrorWhenProceedOnErrorFalse(t *testing.T) {
+	proxy := NewProxy(nil, nil, nil, "/devices", "/devices", false)
+	proxy.RegisterCallback(POST_UPDATE, func(ctx context.Context, args ...interface{}) interface{} {
+		panic("boom")
+	})
+
+	var results []CallbackResult
+	for result := range proxy.InvokeCallbacksAsync(context.Background(), POST_UPDATE, false) {
+		results = append(results, result)
+	}
+
+	if len(results) != 1 || results[0].Error == nil {
+		t.Errorf("expected exactly one failing result when proceedOnError is false, got: %+v", results)
+	}
+}
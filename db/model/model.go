@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Model holds process-wide caches shared by reflection-heavy operations
+// against the data model, such as the per-type field name list Query derives
+// to evaluate selector predicates.
+type Model struct {
+	fieldsCacheMutex sync.RWMutex
+	// ChildrenFieldsCache maps a struct type to the exported field names
+	// ChildrenFields has already computed for it, so repeated callers pay the
+	// reflection cost once per type instead of once per call.
+	ChildrenFieldsCache map[reflect.Type][]string
+}
+
+var (
+	modelInstance *Model
+	modelOnce     sync.Once
+)
+
+// GetInstance returns the process-wide Model singleton, creating it on first use.
+func GetInstance() *Model {
+	modelOnce.Do(func() {
+		modelInstance = &Model{}
+	})
+	return modelInstance
+}
+
+// ChildrenFields returns the exported field names of cls's underlying struct
+// type, computing them via reflection the first time a given type is seen and
+// serving every subsequent call for that type out of GetInstance().ChildrenFieldsCache.
+func ChildrenFields(cls interface{}) []string {
+	t := reflect.TypeOf(cls)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return nil
+	}
+
+	m := GetInstance()
+
+	m.fieldsCacheMutex.RLock()
+	names, cached := m.ChildrenFieldsCache[t]
+	m.fieldsCacheMutex.RUnlock()
+	if cached {
+		return names
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		names = append(names, field.Name)
+	}
+
+	m.fieldsCacheMutex.Lock()
+	if m.ChildrenFieldsCache == nil {
+		m.ChildrenFieldsCache = make(map[reflect.Type][]string)
+	}
+	m.ChildrenFieldsCache[t] = names
+	m.fieldsCacheMutex.Unlock()
+
+	return names
+}
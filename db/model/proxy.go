@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/opencord/voltha-lib-go/v3/pkg/log"
 )
@@ -65,6 +66,7 @@ type Proxy struct {
 	Exclusive  bool
 	Callbacks  map[CallbackType]map[string]*CallbackTuple
 	operation  ProxyOperation
+	batchDepth int32
 }
 
 // NewProxy instantiates a new proxy to a specific location
@@ -190,6 +192,24 @@ func (p *Proxy) SetOperation(operation ProxyOperation) {
 	p.operation = operation
 }
 
+// beginBatch marks p as having a Batch applying staged operations against it,
+// so InvokeCallbacksAsync can suppress the per-op notification that would
+// otherwise fire on every write and let the batch's own single aggregated
+// dispatch, once it folds, be the only one observed.
+func (p *Proxy) beginBatch() {
+	atomic.AddInt32(&p.batchDepth, 1)
+}
+
+// endBatch reverses a prior beginBatch once the batch applying against p is done.
+func (p *Proxy) endBatch() {
+	atomic.AddInt32(&p.batchDepth, -1)
+}
+
+// inBatch reports whether p currently has a Batch applying staged operations against it.
+func (p *Proxy) inBatch() bool {
+	return atomic.LoadInt32(&p.batchDepth) > 0
+}
+
 // List will retrieve information from the data model at the specified path location
 // A list operation will force access to persistence storage
 func (p *Proxy) List(ctx context.Context, path string, depth int, deep bool, txid string) (interface{}, error) {
@@ -486,26 +506,23 @@ func (p *Proxy) invoke(ctx context.Context, callback *CallbackTuple, context []i
 	return result, err
 }
 
-// InvokeCallbacks executes all callbacks associated to a specific type
+// InvokeCallbacks executes all callbacks associated to a specific type and blocks
+// until they have all completed, preserving the pre-existing synchronous
+// signature and proceedOnError semantics. It is now a thin wrapper around
+// InvokeCallbacksAsync, which snapshots the callback map and releases p.mutex
+// before running anything, so a slow or re-entrant callback no longer stalls
+// every other writer to this proxy's path. Callers that want to await
+// completion without blocking the caller's goroutine should call
+// InvokeCallbacksAsync directly.
 func (p *Proxy) InvokeCallbacks(ctx context.Context, args ...interface{}) (result interface{}) {
 	callbackType := args[0].(CallbackType)
 	proceedOnError := args[1].(bool)
 	context := args[2:]
 
-	var err error
-
-	if callbacks := p.getCallbacks(callbackType); callbacks != nil {
-		p.mutex.Lock()
-		for _, callback := range callbacks {
-			if result, err = p.invoke(ctx, callback, context); err != nil {
-				if !proceedOnError {
-					log.Info("An error occurred.  Stopping callback invocation")
-					break
-				}
-				log.Info("An error occurred.  Invoking next callback")
-			}
+	for cbResult := range p.InvokeCallbacksAsync(ctx, callbackType, proceedOnError, context...) {
+		if cbResult.Error == nil {
+			result = cbResult.Result
 		}
-		p.mutex.Unlock()
 	}
 
 	return result
@@ -0,0 +1,319 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+// ChangeEventType identifies the kind of mutation described by a ChangeEvent
+type ChangeEventType uint8
+
+// Enumerated list of change event types
+const (
+	ChangeEventAdded ChangeEventType = iota
+	ChangeEventUpdated
+	ChangeEventRemoved
+)
+
+var changeEventTypes = []string{
+	"ADDED",
+	"UPDATED",
+	"REMOVED",
+}
+
+func (t ChangeEventType) String() string {
+	return changeEventTypes[t]
+}
+
+// ChangeEvent describes a single mutation observed at or below a watched path
+type ChangeEvent struct {
+	Type    ChangeEventType
+	Path    string
+	OldData interface{}
+	NewData interface{}
+}
+
+// WatchOverflowPolicy controls what happens to a subscription when its bounded
+// queue is full and a new event needs to be delivered
+type WatchOverflowPolicy uint8
+
+// Enumerated list of overflow policies a Watch subscriber can be given
+const (
+	// WatchDropOldest discards the oldest undelivered event to make room for the new one
+	WatchDropOldest WatchOverflowPolicy = iota
+	// WatchCloseOnOverflow closes the subscription's channel rather than lose ordering
+	WatchCloseOnOverflow
+)
+
+const defaultWatchQueueSize = 64
+
+// WatchOption customizes how a single Watch subscription behaves.
+type WatchOption func(*watchOptions)
+
+type watchOptions struct {
+	policy    WatchOverflowPolicy
+	queueSize int
+}
+
+// WithOverflowPolicy selects how a subscription's bounded queue behaves once it
+// is full; the default is WatchDropOldest.
+func WithOverflowPolicy(policy WatchOverflowPolicy) WatchOption {
+	return func(o *watchOptions) {
+		o.policy = policy
+	}
+}
+
+// WithQueueSize bounds a subscription's per-subscriber event queue; the default
+// is defaultWatchQueueSize.
+func WithQueueSize(size int) WatchOption {
+	return func(o *watchOptions) {
+		o.queueSize = size
+	}
+}
+
+var watchSubscriptionSeq uint64
+
+// watchSubscription is the bookkeeping kept for a single Watch() call
+type watchSubscription struct {
+	id     string
+	prefix string
+	policy WatchOverflowPolicy
+	ch     chan *ChangeEvent
+
+	mutex  sync.Mutex
+	closed bool
+}
+
+func newWatchSubscription(id, prefix string, policy WatchOverflowPolicy, queueSize int) *watchSubscription {
+	if queueSize <= 0 {
+		queueSize = defaultWatchQueueSize
+	}
+	return &watchSubscription{
+		id:     id,
+		prefix: prefix,
+		policy: policy,
+		ch:     make(chan *ChangeEvent, queueSize),
+	}
+}
+
+// matches reports whether path is the watched prefix itself or one of its descendants
+func (s *watchSubscription) matches(path string) bool {
+	if s.prefix == "" || s.prefix == "/" {
+		return true
+	}
+	return path == s.prefix || strings.HasPrefix(path, s.prefix+"/")
+}
+
+// deliver enqueues event, applying the subscription's overflow policy if the
+// bounded queue is already full
+func (s *watchSubscription) deliver(event *ChangeEvent) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.ch <- event:
+		return
+	default:
+	}
+
+	switch s.policy {
+	case WatchDropOldest:
+		select {
+		case <-s.ch:
+		default:
+		}
+		select {
+		case s.ch <- event:
+		default:
+			log.Debugw("watch-subscriber-still-saturated", log.Fields{"id": s.id, "path": s.prefix})
+		}
+	case WatchCloseOnOverflow:
+		log.Errorw("watch-subscriber-overflow-closing", log.Fields{"id": s.id, "path": s.prefix})
+		s.closeLocked()
+	}
+}
+
+func (s *watchSubscription) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closeLocked()
+}
+
+func (s *watchSubscription) closeLocked() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
+
+// watchRegistry tracks the active subscriptions for a single root
+type watchRegistry struct {
+	mutex sync.Mutex
+	subs  map[string]*watchSubscription
+}
+
+var watchRegistries = newRootResourceRegistry()
+
+func getWatchRegistry(r *root) *watchRegistry {
+	return watchRegistries.getOrCreate(r, func() interface{} {
+		return &watchRegistry{subs: make(map[string]*watchSubscription)}
+	}).(*watchRegistry)
+}
+
+// CloseRootWatches discards r's watch registry, if any, closing every
+// subscription still open against it so r can be garbage collected.
+func CloseRootWatches(r *root) {
+	entry := watchRegistries.remove(r)
+	if entry == nil {
+		return
+	}
+
+	wr := entry.(*watchRegistry)
+	wr.mutex.Lock()
+	subs := make([]*watchSubscription, 0, len(wr.subs))
+	for _, s := range wr.subs {
+		subs = append(subs, s)
+	}
+	wr.mutex.Unlock()
+
+	for _, s := range subs {
+		s.close()
+	}
+}
+
+func (wr *watchRegistry) add(sub *watchSubscription) {
+	wr.mutex.Lock()
+	defer wr.mutex.Unlock()
+	wr.subs[sub.id] = sub
+}
+
+func (wr *watchRegistry) remove(id string) {
+	wr.mutex.Lock()
+	sub, ok := wr.subs[id]
+	delete(wr.subs, id)
+	wr.mutex.Unlock()
+	if ok {
+		sub.close()
+	}
+}
+
+// notify fans event out to every subscription whose watched path covers it
+func (wr *watchRegistry) notify(event *ChangeEvent) {
+	wr.mutex.Lock()
+	var matched []*watchSubscription
+	for _, s := range wr.subs {
+		if s.matches(event.Path) {
+			matched = append(matched, s)
+		}
+	}
+	wr.mutex.Unlock()
+
+	for _, s := range matched {
+		s.deliver(event)
+	}
+}
+
+// Watch subscribes to Added/Updated/Removed change notifications for path and
+// everything below it. Events are delivered on the returned channel, bounded by a
+// per-subscriber queue whose size and overflow policy default to
+// defaultWatchQueueSize and WatchDropOldest but can be overridden with
+// WithQueueSize/WithOverflowPolicy; the channel is closed once ctx is cancelled.
+func (p *Proxy) Watch(ctx context.Context, path string, depth int, txid string, opts ...WatchOption) (<-chan *ChangeEvent, error) {
+	if !strings.HasPrefix(path, "/") {
+		log.Errorf("invalid path: %s", path)
+		return nil, fmt.Errorf("invalid path: %s", path)
+	}
+
+	var fullPath string
+	if path == "/" {
+		fullPath = p.getFullPath()
+	} else {
+		fullPath = p.getFullPath() + path
+	}
+
+	p.SetOperation(ProxyWatch)
+	defer p.SetOperation(ProxyNone)
+
+	log.Debugw("proxy-watch", log.Fields{
+		"path":      path,
+		"full":      fullPath,
+		"depth":     depth,
+		"operation": p.GetOperation(),
+	})
+
+	options := &watchOptions{policy: WatchDropOldest, queueSize: defaultWatchQueueSize}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	id := fmt.Sprintf("%s-%d", fullPath, atomic.AddUint64(&watchSubscriptionSeq, 1))
+	sub := newWatchSubscription(id, fullPath, options.policy, options.queueSize)
+
+	registry := getWatchRegistry(p.getRoot())
+	registry.add(sub)
+
+	go func() {
+		<-ctx.Done()
+		registry.remove(id)
+	}()
+
+	return sub.ch, nil
+}
+
+// notifyWatchers turns a POST_ADD/POST_UPDATE/POST_REMOVE callback dispatch into a
+// ChangeEvent for any Watch subscription covering this proxy's path, reusing the
+// same dispatch point InvokeCallbacks already runs on every write.
+func (p *Proxy) notifyWatchers(callbackType CallbackType, context []interface{}) {
+	var eventType ChangeEventType
+	switch callbackType {
+	case POST_ADD:
+		eventType = ChangeEventAdded
+	case POST_UPDATE:
+		eventType = ChangeEventUpdated
+	case POST_REMOVE:
+		eventType = ChangeEventRemoved
+	default:
+		return
+	}
+
+	var oldData, newData interface{}
+	if len(context) > 0 {
+		oldData = context[0]
+	}
+	if len(context) > 1 {
+		newData = context[1]
+	}
+
+	getWatchRegistry(p.getRoot()).notify(&ChangeEvent{
+		Type:    eventType,
+		Path:    p.getFullPath(),
+		OldData: oldData,
+		NewData: newData,
+	})
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import "testing"
+
+func Test_WatchSubscription_Matches_PrefixFiltering(t *testing.T) {
+	sub := newWatchSubscription("id", "/devices/1", WatchDropOldest, 1)
+
+	cases := map[string]bool{
+		"/devices/1":         true,
+		"/devices/1/ports":   true,
+		"/devices/1/ports/2": true,
+		"/devices/12":        false,
+		"/devices":           false,
+		"/devices/2":         false,
+	}
+	for path, want := range cases {
+		if got := sub.matches(path); got != want {
+			t.Errorf("matches(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func Test_WatchSubscription_Matches_RootPrefixMatchesEverything(t *testing.T) {
+	sub := newWatchSubscription("id", "/", WatchDropOldest, 1)
+	for _, path := range []string{"/", "/devices", "/devices/1/ports/2"} {
+		if !sub.matches(path) {
+			t.Errorf("expected a root-prefixed subscription to match %q", path)
+		}
+	}
+}
+
+func Test_WatchSubscription_Deliver_DropsOldestOnOverflow(t *testing.T) {
+	sub := newWatchSubscription("id", "/devices", WatchDropOldest, 2)
+
+	first := &ChangeEvent{Path: "/devices/1"}
+	second := &ChangeEvent{Path: "/devices/2"}
+	third := &ChangeEvent{Path: "/devices/3"}
+
+	sub.deliver(first)
+	sub.deliver(second)
+	sub.deliver(third)
+
+	if got := <-sub.ch; got != second {
+		t.Errorf("expected the oldest event to have been dropped, got %+v", got)
+	}
+	if got := <-sub.ch; got != third {
+		t.Errorf("expected the newest event to survive, got %+v", got)
+	}
+}
+
+func Test_WatchSubscription_Deliver_ClosesOnOverflow(t *testing.T) {
+	sub := newWatchSubscription("id", "/devices", WatchCloseOnOverflow, 1)
+
+	sub.deliver(&ChangeEvent{Path: "/devices/1"})
+	sub.deliver(&ChangeEvent{Path: "/devices/2"})
+
+	if !sub.closed {
+		t.Fatalf("expected the subscription to be closed once its queue overflowed")
+	}
+	if _, ok := <-sub.ch; !ok {
+		t.Errorf("expected one already-queued event to still be readable before the close drains the channel")
+	}
+	if _, ok := <-sub.ch; ok {
+		t.Errorf("expected the channel to be closed after the overflowing event")
+	}
+}
+
+func Test_WatchRegistry_Notify_FansOutToMatchingSubscribersOnly(t *testing.T) {
+	wr := &watchRegistry{subs: make(map[string]*watchSubscription)}
+
+	devices := newWatchSubscription("devices", "/devices", WatchDropOldest, 1)
+	ports := newWatchSubscription("ports", "/ports", WatchDropOldest, 1)
+	wr.add(devices)
+	wr.add(ports)
+
+	event := &ChangeEvent{Path: "/devices/1"}
+	wr.notify(event)
+
+	select {
+	case got := <-devices.ch:
+		if got != event {
+			t.Errorf("expected the matching subscriber to receive the event, got %+v", got)
+		}
+	default:
+		t.Errorf("expected the matching subscriber to have received the event")
+	}
+
+	select {
+	case got := <-ports.ch:
+		t.Errorf("expected the non-matching subscriber to receive nothing, got %+v", got)
+	default:
+	}
+}
+
+func Test_WatchOptions_OverrideDefaults(t *testing.T) {
+	options := &watchOptions{policy: WatchDropOldest, queueSize: defaultWatchQueueSize}
+
+	for _, opt := range []WatchOption{WithOverflowPolicy(WatchCloseOnOverflow), WithQueueSize(4)} {
+		opt(options)
+	}
+
+	if options.policy != WatchCloseOnOverflow {
+		t.Errorf("expected WithOverflowPolicy to select WatchCloseOnOverflow, got %v", options.policy)
+	}
+	if options.queueSize != 4 {
+		t.Errorf("expected WithQueueSize to override the queue size, got %d", options.queueSize)
+	}
+
+	sub := newWatchSubscription("id", "/devices", options.policy, options.queueSize)
+	if sub.policy != WatchCloseOnOverflow {
+		t.Errorf("expected the subscription to be created with the requested overflow policy, got %v", sub.policy)
+	}
+	if cap(sub.ch) != 4 {
+		t.Errorf("expected the subscription's queue to be sized per WithQueueSize, got capacity %d", cap(sub.ch))
+	}
+}
@@ -0,0 +1,411 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/opencord/voltha-lib-go/v3/pkg/log"
+)
+
+// QueryHit is a single node matched by Proxy.Query, together with its
+// fully-qualified path within the data model.
+type QueryHit struct {
+	Path string
+	Data interface{}
+}
+
+// QueryOption customizes how a single Query call is executed.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	allowFullScan bool
+}
+
+// AllowFullScan permits a selector whose leading segment has no predicate to walk
+// the entire top-level collection instead of being rejected outright.
+func AllowFullScan() QueryOption {
+	return func(o *queryOptions) {
+		o.allowFullScan = true
+	}
+}
+
+// selector is a compiled selector expression, e.g.
+// /devices[admin_state=ENABLED]/ports[type=PON]
+type selector struct {
+	segments []selectorSegment
+}
+
+// selectorSegment is one "/name[predicate]" component of a selector
+type selectorSegment struct {
+	name      string
+	predicate predicate
+}
+
+// predicate evaluates a matched node's fields to decide whether it survives
+type predicate interface {
+	eval(fields map[string]interface{}) bool
+}
+
+type comparisonOp uint8
+
+const (
+	opEq comparisonOp = iota
+	opNeq
+	opRegex
+	opLt
+	opGt
+)
+
+type comparison struct {
+	field string
+	op    comparisonOp
+	value string
+	re    *regexp.Regexp
+}
+
+func (c *comparison) eval(fields map[string]interface{}) bool {
+	actual, ok := fields[c.field]
+	if !ok {
+		// A field the item doesn't carry trivially differs from any value.
+		return c.op == opNeq
+	}
+	actualStr := fmt.Sprintf("%v", actual)
+
+	switch c.op {
+	case opEq:
+		return actualStr == c.value
+	case opNeq:
+		return actualStr != c.value
+	case opRegex:
+		return c.re != nil && c.re.MatchString(actualStr)
+	case opLt, opGt:
+		actualNum, errA := strconv.ParseFloat(actualStr, 64)
+		wantNum, errB := strconv.ParseFloat(c.value, 64)
+		if errA != nil || errB != nil {
+			return false
+		}
+		if c.op == opLt {
+			return actualNum < wantNum
+		}
+		return actualNum > wantNum
+	}
+	return false
+}
+
+type logicalOp uint8
+
+const (
+	logicalAnd logicalOp = iota
+	logicalOr
+)
+
+type logical struct {
+	op    logicalOp
+	left  predicate
+	right predicate
+}
+
+func (l *logical) eval(fields map[string]interface{}) bool {
+	if l.op == logicalAnd {
+		return l.left.eval(fields) && l.right.eval(fields)
+	}
+	return l.left.eval(fields) || l.right.eval(fields)
+}
+
+// maxCachedSelectors bounds selectorCache so that callers who build selector
+// strings from per-request values (e.g. embedding a device id) can't grow it
+// without bound; once the cap is hit the whole cache is dropped and rebuilt,
+// which is simple and fine since selectors are expected to be compiled from a
+// small, mostly-static set of query shapes.
+const maxCachedSelectors = 512
+
+var (
+	selectorCacheMutex sync.RWMutex
+	selectorCache = map[string]*selector{}
+)
+
+// compileSelector parses raw into a *selector, caching the result keyed by its
+// string form so repeated queries avoid re-parsing.
+func compileSelector(raw string) (*selector, error) {
+	selectorCacheMutex.RLock()
+	cached, ok := selectorCache[raw]
+	selectorCacheMutex.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	sel, err := parseSelector(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	selectorCacheMutex.Lock()
+	if len(selectorCache) >= maxCachedSelectors {
+		selectorCache = make(map[string]*selector, maxCachedSelectors)
+	}
+	selectorCache[raw] = sel
+	selectorCacheMutex.Unlock()
+
+	return sel, nil
+}
+
+func parseSelector(raw string) (*selector, error) {
+	if !strings.HasPrefix(raw, "/") {
+		return nil, fmt.Errorf("invalid selector: %s", raw)
+	}
+
+	var segments []selectorSegment
+	for _, part := range strings.Split(strings.TrimPrefix(raw, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		name := part
+		var pred predicate
+		if idx := strings.Index(part, "["); idx >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("invalid selector segment: %s", part)
+			}
+			name = part[:idx]
+			p, err := parsePredicate(part[idx+1 : len(part)-1])
+			if err != nil {
+				return nil, err
+			}
+			pred = p
+		}
+		segments = append(segments, selectorSegment{name: name, predicate: pred})
+	}
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty selector: %s", raw)
+	}
+
+	return &selector{segments: segments}, nil
+}
+
+var comparisonPattern = regexp.MustCompile(`^\s*([\w.]+)\s*(=~|!=|=|<|>)\s*"?([^"]*)"?\s*$`)
+
+// parsePredicate parses the contents of a single "[...]" predicate, supporting
+// =, !=, =~ (regex), <, >, and AND/OR combinations of those comparisons. OR is
+// split on first so that, per usual boolean-logic convention, AND binds tighter:
+// "a=1 OR b=2 AND c=3" groups as "a=1 OR (b=2 AND c=3)".
+func parsePredicate(expr string) (predicate, error) {
+	if idx := splitOutsideQuotes(expr, "OR"); idx >= 0 {
+		return parseLogical(expr, idx, len("OR"), logicalOr)
+	}
+	if idx := splitOutsideQuotes(expr, "AND"); idx >= 0 {
+		return parseLogical(expr, idx, len("AND"), logicalAnd)
+	}
+
+	matches := comparisonPattern.FindStringSubmatch(expr)
+	if matches == nil {
+		return nil, fmt.Errorf("invalid predicate: %s", expr)
+	}
+
+	c := &comparison{field: matches[1], value: matches[3]}
+	switch matches[2] {
+	case "=":
+		c.op = opEq
+	case "!=":
+		c.op = opNeq
+	case "<":
+		c.op = opLt
+	case ">":
+		c.op = opGt
+	case "=~":
+		c.op = opRegex
+		re, err := regexp.Compile(matches[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex in predicate %q: %w", expr, err)
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+func parseLogical(expr string, splitAt int, sepLen int, op logicalOp) (predicate, error) {
+	left, err := parsePredicate(expr[:splitAt])
+	if err != nil {
+		return nil, err
+	}
+	right, err := parsePredicate(expr[splitAt+sepLen:])
+	if err != nil {
+		return nil, err
+	}
+	return &logical{op: op, left: left, right: right}, nil
+}
+
+// splitOutsideQuotes returns the index of the first occurrence of sep in expr
+// that is not enclosed in double quotes and is bounded by whitespace (or the
+// start/end of expr) on both sides, so it only matches the AND/OR keyword and
+// not an occurrence of "AND"/"OR" inside a field name or value, or -1 if there
+// is none.
+func splitOutsideQuotes(expr string, sep string) int {
+	inQuotes := false
+	for i := 0; i+len(sep) <= len(expr); i++ {
+		if expr[i] == '"' {
+			inQuotes = !inQuotes
+		}
+		if inQuotes || !strings.HasPrefix(expr[i:], sep) {
+			continue
+		}
+		if i > 0 && !isSpace(expr[i-1]) {
+			continue
+		}
+		if end := i + len(sep); end < len(expr) && !isSpace(expr[end]) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// Query evaluates selectorString against the data model rooted at p, returning
+// every matching node together with its fully-qualified path. Each segment is
+// resolved with the existing List/Get path, and predicates are evaluated against
+// the reflect-based child-field cache that already backs those calls. A selector
+// whose leading segment has no predicate is rejected, since it would otherwise
+// require scanning the entire top-level collection, unless AllowFullScan() is
+// passed.
+func (p *Proxy) Query(ctx context.Context, selectorString string, depth int, deep bool, txid string, opts ...QueryOption) ([]QueryHit, error) {
+	options := &queryOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	sel, err := compileSelector(selectorString)
+	if err != nil {
+		return nil, err
+	}
+
+	if sel.segments[0].predicate == nil && !options.allowFullScan {
+		return nil, fmt.Errorf("selector %q requires a full-tree scan; pass AllowFullScan() to allow it", selectorString)
+	}
+
+	hits := []QueryHit{{}}
+	for _, segment := range sel.segments {
+		var next []QueryHit
+		for _, hit := range hits {
+			matched, err := p.listSegment(ctx, hit, segment, depth, deep, txid)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, matched...)
+		}
+		hits = next
+	}
+
+	return hits, nil
+}
+
+// listSegment resolves one selector segment relative to a single starting hit,
+// returning every child that matches segment.name and satisfies its predicate.
+func (p *Proxy) listSegment(ctx context.Context, from QueryHit, segment selectorSegment, depth int, deep bool, txid string) ([]QueryHit, error) {
+	listPath := from.Path + "/" + segment.name
+
+	raw, err := p.List(ctx, listPath, depth, deep, txid)
+	if err != nil {
+		return nil, err
+	}
+
+	items := reflect.ValueOf(raw)
+	if items.Kind() == reflect.Ptr {
+		items = items.Elem()
+	}
+
+	var results []QueryHit
+	switch items.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < items.Len(); i++ {
+			results = append(results, matchItem(items.Index(i).Interface(), listPath, i, segment)...)
+		}
+	default:
+		results = append(results, matchItem(raw, listPath, -1, segment)...)
+	}
+
+	log.Debugw("proxy-query-segment", log.Fields{"path": listPath, "matches": len(results)})
+
+	return results, nil
+}
+
+// matchItem evaluates segment's predicate against item and, if it survives,
+// derives its fully-qualified path from its "id" field. index is the item's
+// position within the listed collection (-1 if it wasn't a collection); it is
+// used as a fallback disambiguator for items with no "id" field so that
+// distinct siblings never collapse onto the same QueryHit.Path.
+func matchItem(item interface{}, basePath string, index int, segment selectorSegment) []QueryHit {
+	fields := protoFieldsToMap(item)
+	if segment.predicate != nil && !segment.predicate.eval(fields) {
+		return nil
+	}
+
+	path := basePath
+	if id, ok := fields["id"]; ok {
+		path = fmt.Sprintf("%s/%v", basePath, id)
+	} else if index >= 0 {
+		path = fmt.Sprintf("%s/%d", basePath, index)
+	}
+
+	return []QueryHit{{Path: path, Data: item}}
+}
+
+// protoFieldsToMap flattens the exported fields of a protobuf message into a
+// lower_snake_case-keyed map so predicates can compare against them the same way
+// they are named in the selector grammar. The field names themselves come from
+// ChildrenFields, the same reflect-based cache the rest of the data model uses,
+// so a given message type is only ever walked with reflection once.
+func protoFieldsToMap(item interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+
+	v := reflect.ValueOf(item)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return fields
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for _, name := range ChildrenFields(item) {
+		fields[toSnakeCase(name)] = v.FieldByName(name).Interface()
+	}
+
+	return fields
+}
+
+func toSnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
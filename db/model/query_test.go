@@ -0,0 +1,104 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import "testing"
+
+func Test_ParseSelector_ParsesPathAndPredicate(t *testing.T) {
+	sel, err := parseSelector("/devices[admin_state=ENABLED]/ports[type=PON]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sel.segments) != 2 {
+		t.Fatalf("expected 2 segments, got %d", len(sel.segments))
+	}
+	if sel.segments[0].name != "devices" || sel.segments[0].predicate == nil {
+		t.Errorf("unexpected first segment: %+v", sel.segments[0])
+	}
+	if sel.segments[1].name != "ports" || sel.segments[1].predicate == nil {
+		t.Errorf("unexpected second segment: %+v", sel.segments[1])
+	}
+}
+
+func Test_ParseSelector_RejectsSegmentWithoutLeadingSlash(t *testing.T) {
+	if _, err := parseSelector("devices"); err == nil {
+		t.Errorf("expected an error for a selector missing its leading slash")
+	}
+}
+
+func Test_ParsePredicate_AndBindsTighterThanOr(t *testing.T) {
+	pred, err := parsePredicate(`a=1 OR b=2 AND c=3`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l, ok := pred.(*logical)
+	if !ok || l.op != logicalOr {
+		t.Fatalf("expected the outermost predicate to be an OR, got %+v", pred)
+	}
+	if _, ok := l.right.(*logical); !ok {
+		t.Errorf("expected AND to bind tighter and nest under the right-hand side of OR, got %+v", l.right)
+	}
+}
+
+type queryTestItem struct {
+	Id         string
+	AdminState string
+}
+
+func Test_MatchItem_EvaluatesPredicateAgainstCachedFields(t *testing.T) {
+	segment := selectorSegment{name: "devices"}
+	pred, err := parsePredicate("admin_state=ENABLED")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	segment.predicate = pred
+
+	hits := matchItem(&queryTestItem{Id: "1", AdminState: "ENABLED"}, "/devices", 0, segment)
+	if len(hits) != 1 || hits[0].Path != "/devices/1" {
+		t.Errorf("expected the item to match and be keyed by its id, got: %+v", hits)
+	}
+
+	hits = matchItem(&queryTestItem{Id: "2", AdminState: "DISABLED"}, "/devices", 1, segment)
+	if len(hits) != 0 {
+		t.Errorf("expected the item to be filtered out, got: %+v", hits)
+	}
+}
+
+func Test_ChildrenFields_CachesFieldNamesPerType(t *testing.T) {
+	// A type no other test passes to ChildrenFields, so it is not cached yet.
+	type childrenFieldsTestItem struct {
+		Id         string
+		AdminState string
+	}
+
+	before := len(GetInstance().ChildrenFieldsCache)
+
+	names := ChildrenFields(&childrenFieldsTestItem{})
+	if len(names) != 2 {
+		t.Fatalf("expected 2 field names, got %+v", names)
+	}
+
+	after := len(GetInstance().ChildrenFieldsCache)
+	if after != before+1 {
+		t.Errorf("expected ChildrenFields to add one cache entry per type, before=%d after=%d", before, after)
+	}
+
+	ChildrenFields(&childrenFieldsTestItem{})
+	if len(GetInstance().ChildrenFieldsCache) != after {
+		t.Errorf("expected a repeated call for the same type to reuse the cached entry")
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018-present Open Networking Foundation
+
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+
+ * http://www.apache.org/licenses/LICENSE-2.0
+
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import "sync"
+
+// rootResourceRegistry lazily creates and shares a single resource per *root -
+// a watch registry, a callback worker pool, a batch registry - across every
+// Proxy hanging off that root, and lets the resource be torn down once the
+// root it belongs to is no longer in use.
+type rootResourceRegistry struct {
+	mutex     sync.Mutex
+	resources map[*root]interface{}
+}
+
+func newRootResourceRegistry() *rootResourceRegistry {
+	return &rootResourceRegistry{resources: make(map[*root]interface{})}
+}
+
+// getOrCreate returns the existing resource registered for r, or calls create
+// to build one and register it if r has none yet.
+func (rr *rootResourceRegistry) getOrCreate(r *root, create func() interface{}) interface{} {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	if resource, ok := rr.resources[r]; ok {
+		return resource
+	}
+	resource := create()
+	rr.resources[r] = resource
+	return resource
+}
+
+// remove discards r's resource, if any, and returns it so the caller can tear
+// it down; once removed it and everything it owns can be garbage collected
+// along with r itself.
+func (rr *rootResourceRegistry) remove(r *root) interface{} {
+	rr.mutex.Lock()
+	defer rr.mutex.Unlock()
+	resource, ok := rr.resources[r]
+	if !ok {
+		return nil
+	}
+	delete(rr.resources, r)
+	return resource
+}
+
+// CloseRoot releases every per-root resource (active Watch subscriptions, the
+// callback worker pool, and in-flight batch bookkeeping) kept for r, so r and
+// everything those resources were holding onto - goroutines, channels, node
+// trees - can be garbage collected. Callers that discard a root must call
+// this once nothing will use it again.
+func CloseRoot(r *root) {
+	CloseRootWatches(r)
+	CloseRootCallbacks(r)
+	CloseRootBatches(r)
+}